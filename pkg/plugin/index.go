@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	// defaultIndexURL points at the curated catalog of known Trivy plugins.
+	defaultIndexURL = "https://raw.githubusercontent.com/aquasecurity/trivy-plugin-index/main/index.yaml"
+
+	// indexEnvVar lets users (and air-gapped environments) point at a mirror.
+	indexEnvVar = "TRIVY_PLUGIN_INDEX_URL"
+
+	indexFile = "index.yaml"
+)
+
+// Index is the parsed form of the curated plugin catalog (index.yaml).
+type Index struct {
+	Plugins []IndexEntry `yaml:"plugins"`
+}
+
+// IndexEntry describes a single plugin in the catalog.
+type IndexEntry struct {
+	Name        string   `yaml:"name"`
+	Repository  string   `yaml:"repository"`
+	Maintainer  string   `yaml:"maintainer"`
+	Description string   `yaml:"description"`
+	Keywords    []string `yaml:"keywords"`
+
+	// Versions lists the per-version platform artifacts the index curator
+	// has vetted, each with its own checksum/signature, independent of
+	// whatever the repository's own plugin.yaml declares. Install does not
+	// read Versions: a bare-name install takes only Repository from the
+	// index and verifies the artifact against the downloaded plugin.yaml.
+	Versions []IndexedVersion `yaml:"versions,omitempty"`
+}
+
+// IndexedVersion is a single released version of an indexed plugin, with the
+// same per-platform artifact/checksum shape plugin.yaml itself uses.
+type IndexedVersion struct {
+	Version   string     `yaml:"version"`
+	Platforms []Platform `yaml:"platforms"`
+}
+
+// indexURL returns the configured (or default) source for the plugin index.
+func indexURL() string {
+	if u := os.Getenv(indexEnvVar); u != "" {
+		return u
+	}
+	return defaultIndexURL
+}
+
+// indexCachePath returns where the fetched index.yaml is cached on disk.
+func indexCachePath() (string, error) {
+	dataHome, err := dataHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, ".trivy", "plugin-index", indexFile), nil
+}
+
+// UpdateIndex fetches the latest plugin index and caches it locally so that
+// SearchIndex and bare-name installs work offline afterwards.
+func UpdateIndex(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL(), nil)
+	if err != nil {
+		return xerrors.Errorf("unable to build the index request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("unable to fetch the plugin index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("unable to fetch the plugin index: unexpected status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return xerrors.Errorf("unable to read the plugin index: %w", err)
+	}
+
+	// Validate before caching so a broken mirror can't poison subsequent searches.
+	var index Index
+	if err = yaml.Unmarshal(data, &index); err != nil {
+		return xerrors.Errorf("unable to parse the plugin index: %w", err)
+	}
+
+	cachePath, err := indexCachePath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(cachePath), os.ModePerm); err != nil {
+		return xerrors.Errorf("unable to create the plugin index cache dir: %w", err)
+	}
+	if err = os.WriteFile(cachePath, data, 0o644); err != nil {
+		return xerrors.Errorf("unable to cache the plugin index: %w", err)
+	}
+
+	log.Logger.Infof("Updated the plugin index (%d plugins)", len(index.Plugins))
+	return nil
+}
+
+// loadIndex loads the cached plugin index, fetching it first if it isn't
+// cached yet.
+func loadIndex(ctx context.Context) (Index, error) {
+	cachePath, err := indexCachePath()
+	if err != nil {
+		return Index{}, err
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if os.IsNotExist(err) {
+		if err = UpdateIndex(ctx); err != nil {
+			return Index{}, xerrors.Errorf("unable to fetch the plugin index: %w", err)
+		}
+		if data, err = os.ReadFile(cachePath); err != nil {
+			return Index{}, xerrors.Errorf("unable to read the plugin index: %w", err)
+		}
+	} else if err != nil {
+		return Index{}, xerrors.Errorf("unable to read the plugin index: %w", err)
+	}
+
+	var index Index
+	if err = yaml.Unmarshal(data, &index); err != nil {
+		return Index{}, xerrors.Errorf("unable to parse the plugin index: %w", err)
+	}
+	return index, nil
+}
+
+// SearchIndex returns every indexed plugin whose name, description or
+// keywords contain query (case-insensitive). An empty query returns the
+// full catalog.
+func SearchIndex(ctx context.Context, query string) ([]IndexEntry, error) {
+	index, err := loadIndex(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to load the plugin index: %w", err)
+	}
+
+	if query == "" {
+		return index.Plugins, nil
+	}
+
+	query = strings.ToLower(query)
+	var matched []IndexEntry
+	for _, entry := range index.Plugins {
+		if strings.Contains(strings.ToLower(entry.Name), query) ||
+			strings.Contains(strings.ToLower(entry.Description), query) {
+			matched = append(matched, entry)
+			continue
+		}
+		for _, keyword := range entry.Keywords {
+			if strings.Contains(strings.ToLower(keyword), query) {
+				matched = append(matched, entry)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// resolveIndexURL resolves a bare plugin name (e.g. "kubectl") to its
+// repository URL via the plugin index. It returns an empty string, with no
+// error, when name isn't a bare name or isn't found in the index so callers
+// can fall back to treating it as a URL themselves.
+func resolveIndexURL(ctx context.Context, name string) (string, error) {
+	if !isBareName(name) {
+		return "", nil
+	}
+
+	index, err := loadIndex(ctx)
+	if err != nil {
+		// The index may simply be unavailable (offline, first run with no
+		// network access); let the caller fall back to go-getter, which
+		// will produce a clearer "not found" error for a bad bare name.
+		log.Logger.Debugf("plugin index unavailable, falling back to direct install: %s", err)
+		return "", nil
+	}
+
+	for _, entry := range index.Plugins {
+		if entry.Name == name {
+			return entry.Repository, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,124 @@
+package plugin_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aquasecurity/trivy/pkg/plugin"
+)
+
+const testIndexYAML = `
+plugins:
+  - name: kubectl
+    repository: github.com/aquasecurity/trivy-plugin-kubectl
+    maintainer: aquasecurity
+    description: Scan Kubernetes resources
+    keywords:
+      - kubernetes
+      - k8s
+  - name: referrer
+    repository: github.com/aquasecurity/trivy-plugin-referrer
+    maintainer: aquasecurity
+    description: Show OCI referrers
+    keywords:
+      - oci
+    versions:
+      - version: v1.0.0
+        platforms:
+          - selector:
+              os: linux
+              arch: amd64
+            uri: ./referrer
+            bin: ./referrer
+            sha256: 299001868fb8c02fd431c336c6d058f5558c5dff5b5af5e6fe04b870a6a9cbba
+`
+
+func TestSearchIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testIndexYAML))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TRIVY_PLUGIN_INDEX_URL", srv.URL)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "match by name",
+			query: "kubectl",
+			want:  []string{"kubectl"},
+		},
+		{
+			name:  "match by keyword",
+			query: "k8s",
+			want:  []string{"kubectl"},
+		},
+		{
+			name:  "no match",
+			query: "nonexistent",
+			want:  nil,
+		},
+		{
+			name:  "empty query returns everything",
+			query: "",
+			want:  []string{"kubectl", "referrer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := plugin.SearchIndex(context.Background(), tt.query)
+			require.NoError(t, err)
+
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestSearchIndexVersions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testIndexYAML))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TRIVY_PLUGIN_INDEX_URL", srv.URL)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	entries, err := plugin.SearchIndex(context.Background(), "referrer")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.Len(t, entries[0].Versions, 1)
+	assert.Equal(t, "v1.0.0", entries[0].Versions[0].Version)
+	require.Len(t, entries[0].Versions[0].Platforms, 1)
+	assert.Equal(t, "299001868fb8c02fd431c336c6d058f5558c5dff5b5af5e6fe04b870a6a9cbba", entries[0].Versions[0].Platforms[0].SHA256)
+}
+
+func TestUpdateIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testIndexYAML))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TRIVY_PLUGIN_INDEX_URL", srv.URL)
+	dst := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dst)
+
+	err := plugin.UpdateIndex(context.Background())
+	require.NoError(t, err)
+
+	assert.FileExists(t, dst+"/.trivy/plugin-index/index.yaml")
+}
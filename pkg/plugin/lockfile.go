@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+const lockFile = "plugins.lock.yaml"
+
+// Lockfile records the resolved version and artifact digest of every plugin
+// ever installed on this machine, so installs can be reproduced elsewhere.
+type Lockfile struct {
+	Plugins []LockedPlugin `yaml:"plugins"`
+}
+
+// LockedPlugin is a single Lockfile entry.
+type LockedPlugin struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Repository string `yaml:"repository"`
+	Digest     string `yaml:"digest,omitempty"`
+}
+
+func lockfilePath() (string, error) {
+	dataHome, err := dataHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, ".trivy", lockFile), nil
+}
+
+func loadLockfile() (Lockfile, error) {
+	path, err := lockfilePath()
+	if err != nil {
+		return Lockfile{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Lockfile{}, nil
+	} else if err != nil {
+		return Lockfile{}, xerrors.Errorf("unable to read %s: %w", lockFile, err)
+	}
+
+	var lock Lockfile
+	if err = yaml.Unmarshal(data, &lock); err != nil {
+		return Lockfile{}, xerrors.Errorf("unable to parse %s: %w", lockFile, err)
+	}
+	return lock, nil
+}
+
+// recordLock upserts p's resolved version and the given artifact digest
+// (a hex-encoded SHA256 of the actually-fetched binary, not merely the
+// declared checksum) into the lockfile.
+func recordLock(p Plugin, digest string) error {
+	path, err := lockfilePath()
+	if err != nil {
+		return err
+	}
+
+	lock, err := loadLockfile()
+	if err != nil {
+		return err
+	}
+
+	entry := LockedPlugin{
+		Name:       p.Name,
+		Version:    p.Version,
+		Repository: p.Repository,
+		Digest:     digest,
+	}
+
+	found := false
+	for i, e := range lock.Plugins {
+		if e.Name == p.Name {
+			lock.Plugins[i] = entry
+			found = true
+			break
+		}
+	}
+	if !found {
+		lock.Plugins = append(lock.Plugins, entry)
+	}
+
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return xerrors.Errorf("unable to marshal %s: %w", lockFile, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return xerrors.Errorf("unable to create the dir for %s: %w", lockFile, err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
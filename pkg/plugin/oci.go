@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/xerrors"
+)
+
+const (
+	ociScheme = "oci://"
+
+	// OCIPluginConfigMediaType is the media type of the plugin.yaml blob
+	// stored as the config of an OCI plugin artifact.
+	OCIPluginConfigMediaType = "application/vnd.trivy.plugin.config.v1+json"
+	// OCIPluginLayerMediaType is the media type of each per-platform binary
+	// layer in an OCI plugin artifact.
+	OCIPluginLayerMediaType = "application/vnd.trivy.plugin.layer.v1.tar+gzip"
+
+	// ociPlatformAnnotation records the "GOOS/GOARCH" a layer targets as an
+	// OCI descriptor annotation, since a single-manifest artifact has no
+	// platform-indexed layers the way a multi-arch image index does.
+	ociPlatformAnnotation = "org.opencontainers.image.platform"
+)
+
+// downloadOCIToTempDir pulls the OCI plugin artifact at ref (an "oci://..."
+// reference) into a temp dir laid out like any other plugin source: the
+// config blob written out as plugin.yaml, and the single binary layer
+// matching runtime.GOOS/GOARCH unpacked alongside it.
+//
+// It uses go-containerregistry directly rather than go-getter so that Trivy's
+// usual registry credential chain (docker config, cloud-provider keychains)
+// applies the same way it does for image scanning, making private
+// registries and pull-through caches work without extra configuration.
+func downloadOCIToTempDir(ctx context.Context, ref string) (string, error) {
+	ref = strings.TrimPrefix(ref, ociScheme)
+
+	tag, err := name.ParseReference(ref)
+	if err != nil {
+		return "", xerrors.Errorf("invalid OCI reference %q: %w", ref, err)
+	}
+
+	img, err := remote.Image(tag, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return "", xerrors.Errorf("unable to pull %q: %w", ref, err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return "", xerrors.Errorf("unable to read the manifest of %q: %w", ref, err)
+	}
+	if manifest.Config.MediaType != OCIPluginConfigMediaType {
+		return "", xerrors.Errorf("%q is not a Trivy plugin artifact (unexpected config media type %q)", ref, manifest.Config.MediaType)
+	}
+
+	tempDir, err := os.MkdirTemp("", "trivy-plugin-oci")
+	if err != nil {
+		return "", xerrors.Errorf("unable to create a temp dir: %w", err)
+	}
+
+	config, err := img.RawConfigFile()
+	if err != nil {
+		return "", xerrors.Errorf("unable to read the plugin config of %q: %w", ref, err)
+	}
+	if err = os.WriteFile(filepath.Join(tempDir, metadataFile), config, 0o644); err != nil {
+		return "", xerrors.Errorf("unable to write %s: %w", metadataFile, err)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return "", xerrors.Errorf("unable to read the layers of %q: %w", ref, err)
+	}
+
+	wantPlatform := runtime.GOOS + "/" + runtime.GOARCH
+	for i, layer := range layers {
+		desc := manifest.Layers[i]
+		if desc.MediaType.String() != OCIPluginLayerMediaType {
+			continue
+		}
+		if desc.Annotations[ociPlatformAnnotation] != wantPlatform {
+			continue
+		}
+		if err = extractLayer(layer, tempDir); err != nil {
+			return "", xerrors.Errorf("unable to extract the %s layer of %q: %w", wantPlatform, ref, err)
+		}
+		return tempDir, nil
+	}
+
+	return "", xerrors.Errorf("no layer for platform %s found in %q", wantPlatform, ref)
+}
+
+// extractLayer unpacks an OCIPluginLayerMediaType (tar+gzip) layer into dst.
+func extractLayer(layer interface{ Compressed() (io.ReadCloser, error) }, dst string) error {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return xerrors.Errorf("unable to read the layer blob: %w", err)
+	}
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return xerrors.Errorf("unable to decompress the layer: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("unable to read the layer tar: %w", err)
+		}
+
+		target := filepath.Join(dst, hdr.Name)
+		if target != dst && !strings.HasPrefix(target, dst+string(os.PathSeparator)) {
+			return xerrors.Errorf("plugin layer entry %q escapes the extraction dir", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, os.ModePerm); err != nil {
+				return xerrors.Errorf("unable to create %s: %w", target, err)
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return xerrors.Errorf("unable to create %s: %w", filepath.Dir(target), err)
+			}
+			if err = writeTarEntry(target, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeTarEntry(target string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return xerrors.Errorf("unable to create %s: %w", target, err)
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(f, r); err != nil {
+		return xerrors.Errorf("unable to write %s: %w", target, err)
+	}
+	return nil
+}
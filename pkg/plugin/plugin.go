@@ -0,0 +1,456 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+const (
+	metadataFile = "plugin.yaml"
+
+	summaryTpl = `
+Plugin: %s
+  Description: %s
+  Version:     %s
+  Usage:       %s
+`
+)
+
+// Plugin represents the plugin.yaml of an installed plugin
+type Plugin struct {
+	Name        string     `yaml:"name"`
+	Repository  string     `yaml:"repository"`
+	Version     string     `yaml:"version"`
+	Usage       string     `yaml:"usage"`
+	Description string     `yaml:"description"`
+	Platforms   []Platform `yaml:"platforms"`
+
+	// VersionConstraint is the semver constraint (e.g. "^1.2") this plugin
+	// was installed with, if any. Update() re-resolves against it instead of
+	// always taking the latest release.
+	VersionConstraint string `yaml:"versionConstraint,omitempty"`
+
+	// Hooks lists the scan-pipeline hooks (see Hook) this plugin handles via
+	// the structured stdin/stdout protocol. A plugin with no Hooks is a
+	// plain script invoked only through `trivy plugin run`.
+	Hooks []string `yaml:"hooks,omitempty"`
+
+	// Permissions declares what the plugin is allowed to touch when run. A
+	// nil Permissions means the plugin inherits the full user environment,
+	// unsandboxed, as before this field existed.
+	Permissions *Permissions `yaml:"permissions,omitempty"`
+
+	// GOOS and GOARCH are not persisted in plugin.yaml.
+	// They are populated at load/install time so that Run() can pick
+	// the right platform-specific artifact.
+	GOOS   string `yaml:"-"`
+	GOARCH string `yaml:"-"`
+
+	// Dir is the plugin search path entry this plugin was loaded from (see
+	// LoadAll), so callers like `trivy plugin list` can show provenance.
+	Dir string `yaml:"-"`
+}
+
+// Platform represents the download and execution details for a given OS/Arch
+type Platform struct {
+	Selector *Selector `yaml:"selector,omitempty"`
+	URI      string    `yaml:"uri"`
+	Bin      string    `yaml:"bin"`
+
+	// SHA256 is the expected checksum of Bin, checked before install.
+	SHA256 string `yaml:"sha256,omitempty"`
+	// Signature is a detached cosign signature for Bin, verified keylessly
+	// against CertificateIdentity and Rekor.
+	Signature string `yaml:"signature,omitempty"`
+	// CertificateIdentity pins the expected cosign keyless signer.
+	CertificateIdentity *CertificateIdentity `yaml:"certificateIdentity,omitempty"`
+}
+
+// Selector represents the OS/Arch combination a platform supports.
+// A nil Selector on the enclosing Platform means "matches everything".
+type Selector struct {
+	OS   string `yaml:"os"`
+	Arch string `yaml:"arch"`
+}
+
+// RunOptions represents options for Plugin.Run()
+type RunOptions struct {
+	Args []string
+}
+
+// Install installs a plugin from url, optionally pinned with a trailing
+// "@version" or "@constraint" (e.g. "repo@v1.2.0", "repo@^1.2"), mirroring
+// mkr's plugin install syntax.
+// Bare names without a scheme (e.g. "kubectl") are resolved against the
+// plugin index before falling back to the go-getter URL behavior, so
+// users don't need to know the exact repository URL up front.
+// An "oci://" url pulls the plugin from an OCI registry instead.
+// Unless insecure is true, the platform binary matching GOOS/GOARCH must
+// carry a verifiable checksum or signature or Install refuses to proceed.
+// If the plugin declares Permissions, acceptPermissions must be true (mirroring
+// a `--accept-permissions` flag) or Install refuses to finish, since a plugin
+// whose privileges are never accepted could otherwise never be run.
+func Install(ctx context.Context, url string, quiet, insecure, acceptPermissions bool) (Plugin, error) {
+	isOCI := strings.HasPrefix(url, ociScheme)
+
+	var constraint string
+	if !isOCI {
+		url, constraint = splitVersionConstraint(url)
+
+		if resolved, err := resolveIndexURL(ctx, url); err != nil {
+			return Plugin{}, xerrors.Errorf("unable to resolve %q via the plugin index: %w", url, err)
+		} else if resolved != "" {
+			log.Logger.Infof("Resolved plugin %q to %q via the plugin index", url, resolved)
+			url = resolved
+		}
+	}
+
+	src := url
+	if constraint != "" {
+		tag, err := resolveVersion(ctx, url, constraint)
+		if err != nil {
+			return Plugin{}, xerrors.Errorf("unable to resolve version %q of %s: %w", constraint, url, err)
+		}
+		src = fmt.Sprintf("%s?ref=%s", url, tag)
+		log.Logger.Infof("Resolved %s@%s to %s", url, constraint, tag)
+	}
+
+	log.Logger.Infof("Installing the plugin from %s...", src)
+
+	var tempDir string
+	var err error
+	if isOCI {
+		tempDir, err = downloadOCIToTempDir(ctx, src)
+	} else {
+		tempDir, err = downloadToTempDir(ctx, src)
+	}
+	if err != nil {
+		return Plugin{}, xerrors.Errorf("unable to download the plugin: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	plugin, err := loadMetadata(tempDir)
+	if err != nil {
+		return Plugin{}, xerrors.Errorf("unable to load the plugin metadata: %w", err)
+	}
+	plugin.GOOS = runtime.GOOS
+	plugin.GOARCH = runtime.GOARCH
+	plugin.VersionConstraint = constraint
+	if constraint != "" {
+		// The downloaded plugin.yaml has no versionConstraint of its own, so
+		// without this Update would re-read an empty constraint from disk
+		// and silently jump to the latest release on the next update.
+		if err = saveMetadata(tempDir, plugin); err != nil {
+			return Plugin{}, xerrors.Errorf("unable to persist the resolved version constraint: %w", err)
+		}
+	}
+
+	if plugin.Permissions != nil && !acceptPermissions {
+		return Plugin{}, xerrors.Errorf("plugin %s declares permissions that must be reviewed and accepted; pass --accept-permissions to install it", plugin.Name)
+	}
+
+	platform, err := plugin.selectPlatform()
+	if err != nil {
+		return Plugin{}, err
+	}
+	if platform.Signature != "" && !strings.Contains(platform.Signature, "://") && !filepath.IsAbs(platform.Signature) {
+		// A relative signature path refers to a file shipped with the plugin.
+		platform.Signature = filepath.Join(tempDir, platform.Signature)
+	}
+	if err = verifyArtifact(filepath.Join(tempDir, platform.Bin), platform, insecure); err != nil {
+		return Plugin{}, xerrors.Errorf("unable to verify the plugin artifact: %w", err)
+	}
+	digest, err := hashFile(filepath.Join(tempDir, platform.Bin))
+	if err != nil {
+		return Plugin{}, xerrors.Errorf("unable to hash the plugin artifact: %w", err)
+	}
+
+	dir, err := PluginDir(plugin.Name)
+	if err != nil {
+		return Plugin{}, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return Plugin{}, xerrors.Errorf("unable to create the plugin dir: %w", err)
+	}
+	// Remove any previous install of the same plugin so upgrades don't leave stale files behind.
+	if err = os.RemoveAll(dir); err != nil {
+		return Plugin{}, xerrors.Errorf("unable to remove the existing plugin: %w", err)
+	}
+	if err = os.Rename(tempDir, dir); err != nil {
+		return Plugin{}, xerrors.Errorf("unable to move the plugin into place: %w", err)
+	}
+
+	if plugin.Permissions != nil {
+		// acceptPermissions was already required to reach this point, so
+		// recording it now is what lets Run() execute the plugin later
+		// instead of forever bouncing off its own permissions check.
+		if err = AcceptPermissions(plugin); err != nil {
+			log.Logger.Warnf("unable to record the accepted permissions for %s: %s", plugin.Name, err)
+		}
+	}
+
+	if !quiet {
+		log.Logger.Infof("Installed plugin %s", plugin.Name)
+	}
+
+	if err = recordLock(plugin, digest); err != nil {
+		// The lockfile is a reproducibility aid, not load-bearing for the install itself.
+		log.Logger.Warnf("unable to update %s: %s", lockFile, err)
+	}
+
+	return plugin, nil
+}
+
+// downloadToTempDir fetches the plugin source (local path, git/http URL, etc.)
+// into a temporary directory using go-getter.
+func downloadToTempDir(ctx context.Context, url string) (string, error) {
+	tempDir, err := os.MkdirTemp("", "trivy-plugin")
+	if err != nil {
+		return "", xerrors.Errorf("unable to create a temp dir: %w", err)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the current dir: %w", err)
+	}
+
+	client := getter.Client{
+		Ctx:  ctx,
+		Src:  url,
+		Dst:  tempDir,
+		Pwd:  pwd,
+		Mode: getter.ClientModeDir,
+	}
+	if err = client.Get(); err != nil {
+		return "", xerrors.Errorf("unable to fetch the plugin: %w", err)
+	}
+	return tempDir, nil
+}
+
+// saveMetadata writes p back to dir's plugin.yaml. It's used to persist
+// fields Install resolves at install time, such as VersionConstraint, that
+// aren't present in the plugin.yaml as downloaded.
+func saveMetadata(dir string, p Plugin) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return xerrors.Errorf("unable to marshal %s: %w", metadataFile, err)
+	}
+	return os.WriteFile(filepath.Join(dir, metadataFile), data, 0o644)
+}
+
+// loadMetadata reads and parses the plugin.yaml found in dir.
+func loadMetadata(dir string) (Plugin, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metadataFile))
+	if err != nil {
+		return Plugin{}, xerrors.Errorf("file open error: %w", err)
+	}
+
+	var plugin Plugin
+	if err = yaml.Unmarshal(data, &plugin); err != nil {
+		return Plugin{}, xerrors.Errorf("yaml unmarshal error: %w", err)
+	}
+
+	// Fall back to the directory name when plugin.yaml doesn't declare one.
+	if plugin.Name == "" {
+		plugin.Name = filepath.Base(dir)
+	}
+
+	return plugin, nil
+}
+
+// Uninstall removes the named plugin from wherever it's installed. It
+// refuses to remove a plugin installed outside the user's own plugin dir
+// (e.g. a distro-managed system install), since that needs different
+// privileges than `trivy plugin uninstall` has.
+func Uninstall(name string) error {
+	userDir, err := PluginDir(name)
+	if err != nil {
+		return xerrors.Errorf("unable to resolve the plugin dir: %w", err)
+	}
+	if _, err = os.Stat(userDir); err == nil {
+		return os.RemoveAll(userDir)
+	}
+
+	plugins, err := LoadAll()
+	if err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return xerrors.Errorf("%s is managed outside your personal plugin dir (found in %s); remove it there instead", name, plugin.Dir)
+		}
+	}
+
+	// Nothing installed under that name anywhere; removing a non-existent
+	// user dir is a no-op, matching the pre-search-path behavior.
+	return os.RemoveAll(userDir)
+}
+
+// Information returns a human-readable summary of the named, installed
+// plugin, searched across the whole plugin search path.
+func Information(name string) (string, error) {
+	plugins, err := LoadAll()
+	if err != nil {
+		return "", err
+	}
+
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return fmt.Sprintf(summaryTpl, plugin.Name, plugin.Description, plugin.Version, plugin.Usage), nil
+		}
+	}
+
+	return "", xerrors.Errorf("could not find a plugin called '%s', did you install it?", name)
+}
+
+// LoadAll loads the metadata of every installed plugin across the plugin
+// search path: each TRIVY_PLUGIN_PATH entry, then the user's own
+// XDG_DATA_HOME plugins dir, then the built-in system dir, in that
+// precedence order. Plugins are deduplicated by name, earlier (more
+// user-specific) directories winning; Plugin.Dir records where each one was
+// actually found.
+func LoadAll() ([]Plugin, error) {
+	dirs, err := pluginSearchPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadAllFrom(dirs)
+}
+
+// Update reinstalls the named plugin from its recorded repository, honoring
+// any version constraint it was originally installed with. acceptPermissions
+// is forwarded to Install; a plugin that already declared Permissions and was
+// accepted on a prior install keeps working regardless, since Update checks
+// the existing install's acceptance marker before reinstalling and Install
+// then records acceptance again in the new install dir.
+func Update(name string, insecure, acceptPermissions bool) error {
+	plugins, err := LoadAll()
+	if err != nil {
+		return xerrors.Errorf("unable to load the installed plugins: %w", err)
+	}
+
+	for _, plugin := range plugins {
+		if plugin.Name != name {
+			continue
+		}
+		url := plugin.Repository
+		if plugin.VersionConstraint != "" {
+			url = fmt.Sprintf("%s@%s", url, plugin.VersionConstraint)
+		}
+		if _, err = Install(context.Background(), url, true, insecure, acceptPermissions || permissionsAccepted(plugin)); err != nil {
+			return xerrors.Errorf("unable to update the plugin: %w", err)
+		}
+		return nil
+	}
+
+	return xerrors.Errorf("could not find a plugin called '%s', did you install it?", name)
+}
+
+// Run executes the plugin binary matching the current (or overridden, for
+// testing) GOOS/GOARCH, streaming the parent's stdio through to it.
+func (p Plugin) Run(ctx context.Context, opts RunOptions) error {
+	dir, err := pluginInstallDir(p)
+	if err != nil {
+		return err
+	}
+
+	platform, err := p.selectPlatform()
+	if err != nil {
+		return err
+	}
+
+	if p.Permissions != nil && !permissionsAccepted(p) {
+		return xerrors.Errorf("plugin %s declares permissions that have not been accepted; review them in plugin.yaml and run `trivy plugin install --accept-permissions %s`", p.Name, p.Name)
+	}
+
+	execFile := filepath.Join(dir, platform.Bin)
+	cmd, cleanup := sandboxCommand(ctx, execFile, opts.Args, p.Permissions)
+	defer cleanup()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err = runSandboxedCommand(cmd, p.Permissions); err != nil {
+		return xerrors.Errorf("plugin exec: %w", err)
+	}
+	return nil
+}
+
+// pluginInstallDir returns the directory p's files actually live in: p.Dir
+// joined with p.Name when p.Dir is set (p was found by LoadAll, possibly
+// outside the user's own plugin dir), or the user's own plugin dir
+// otherwise, which is also where a freshly Installed plugin (with no Dir
+// set yet) always lives.
+func pluginInstallDir(p Plugin) (string, error) {
+	if p.Dir != "" {
+		return filepath.Join(p.Dir, p.Name), nil
+	}
+	return PluginDir(p.Name)
+}
+
+// selectPlatform returns the first Platform whose Selector matches the
+// plugin's GOOS/GOARCH, or the first Selector-less Platform, whichever comes
+// first in the list.
+func (p Plugin) selectPlatform() (Platform, error) {
+	goos, goarch := p.GOOS, p.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+
+	for _, platform := range p.Platforms {
+		if platform.Selector == nil {
+			return platform, nil
+		}
+		if platform.Selector.OS == goos && platform.Selector.Arch == goarch {
+			return platform, nil
+		}
+	}
+
+	return Platform{}, xerrors.New("platform not found")
+}
+
+// PluginDir returns the directory the named plugin is (or will be) installed in.
+func PluginDir(name string) (string, error) {
+	dataHome, err := dataHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataHome, ".trivy", "plugins", name), nil
+}
+
+// dataHomeDir returns XDG_DATA_HOME, falling back to ~/.local/share.
+func dataHomeDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return dataHome, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", xerrors.Errorf("unable to get the current user's home dir: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "share"), nil
+}
+
+// isBareName reports whether url looks like a short plugin name (e.g.
+// "kubectl") rather than a URL or filesystem path that go-getter already
+// understands.
+func isBareName(url string) bool {
+	if url == "" {
+		return false
+	}
+	return !strings.ContainsAny(url, "/\\:@") && url != "." && url != ".."
+}
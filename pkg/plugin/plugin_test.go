@@ -28,6 +28,7 @@ func TestPlugin_Run(t *testing.T) {
 		Platforms   []plugin.Platform
 		GOOS        string
 		GOARCH      string
+		Dir         string
 	}
 	tests := []struct {
 		name    string
@@ -142,6 +143,32 @@ func TestPlugin_Run(t *testing.T) {
 			},
 			wantErr: "exit status 1",
 		},
+		{
+			name: "installed outside the user plugin dir",
+			fields: fields{
+				Name:        "test_plugin",
+				Repository:  "github.com/aquasecurity/trivy-plugin-test",
+				Version:     "0.1.0",
+				Usage:       "test",
+				Description: "test",
+				Platforms: []plugin.Platform{
+					{
+						Selector: &plugin.Selector{
+							OS:   "linux",
+							Arch: "amd64",
+						},
+						URI: "github.com/aquasecurity/trivy-plugin-test",
+						Bin: "test.sh",
+					},
+				},
+				GOOS:   "linux",
+				GOARCH: "amd64",
+				// A system- or TRIVY_PLUGIN_PATH-installed plugin has no
+				// test.sh under XDG_DATA_HOME, so Run must resolve the
+				// binary under Dir instead, not the user's own plugin dir.
+				Dir: "testdata/system",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -157,6 +184,7 @@ func TestPlugin_Run(t *testing.T) {
 				Platforms:   tt.fields.Platforms,
 				GOOS:        tt.fields.GOOS,
 				GOARCH:      tt.fields.GOARCH,
+				Dir:         tt.fields.Dir,
 			}
 
 			err := p.Run(context.Background(), tt.opts)
@@ -263,7 +291,9 @@ func TestInstall(t *testing.T) {
 			dst := t.TempDir()
 			os.Setenv("XDG_DATA_HOME", dst)
 
-			got, err := plugin.Install(context.Background(), tt.url, false)
+			// These fixtures predate checksum/signature verification, so
+			// install with --plugin-insecure semantics (insecure=true).
+			got, err := plugin.Install(context.Background(), tt.url, false, true, false)
 			if tt.wantErr != "" {
 				require.NotNil(t, err)
 				assert.Contains(t, err.Error(), tt.wantErr)
@@ -371,13 +401,18 @@ func TestLoadAll1(t *testing.T) {
 					},
 					GOOS:   "linux",
 					GOARCH: "amd64",
+					Dir:    filepath.Join("testdata", ".trivy", "plugins"),
 				},
 			},
 		},
 		{
-			name:    "sad path",
-			dir:     "sad",
-			wantErr: "no such file or directory",
+			// A missing plugin dir is no longer a hard error: it's one
+			// optional entry among several in the plugin search path (see
+			// TRIVY_PLUGIN_PATH), and most of those won't exist on a given
+			// machine.
+			name: "sad path",
+			dir:  "sad",
+			want: nil,
 		},
 	}
 	for _, tt := range tests {
@@ -427,7 +462,7 @@ description: A simple test plugin`
 	verifyVersion(t, pluginName, "0.0.5")
 
 	// Update the existing plugin
-	err = plugin.Update(pluginName)
+	err = plugin.Update(pluginName, true, false)
 	require.NoError(t, err)
 
 	// verify plugin updated
@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// Hook identifies a point in the scan pipeline a plugin can attach to.
+type Hook string
+
+const (
+	// HookPostScan runs after scanning completes, before the report is
+	// rendered, letting plugins merge additional findings.
+	HookPostScan Hook = "postScan"
+	// HookPreReport runs just before the report is rendered, letting
+	// plugins transform or annotate the final result set.
+	HookPreReport Hook = "preReport"
+	// HookOutput replaces or augments report rendering, e.g. a custom
+	// notifier format.
+	HookOutput Hook = "output"
+	// HookCommand marks a plugin invoked directly as a `trivy plugin run`
+	// subcommand rather than from the scan pipeline.
+	HookCommand Hook = "command"
+)
+
+// PluginInvocation is piped as JSON to a hook plugin's stdin.
+type PluginInvocation struct {
+	TrivyVersion string `json:"trivyVersion"`
+	Hook         Hook   `json:"hook"`
+	TargetKind   string `json:"targetKind"`
+	ResultsPath  string `json:"resultsPath"`
+	ConfigDir    string `json:"configDir"`
+}
+
+// PluginResponse is parsed from a hook plugin's stdout.
+type PluginResponse struct {
+	// Findings are additional findings, in Trivy's own result JSON shape,
+	// to merge into the scan results.
+	Findings []json.RawMessage `json:"findings,omitempty"`
+	// ExitCode, if set, overrides Trivy's own exit code decision.
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Commands are follow-up shell commands the CLI should run, e.g. to
+	// upload a report a notifier plugin just generated.
+	Commands []string `json:"commands,omitempty"`
+}
+
+// Dispatch runs every installed plugin that declares hook, piping payload to
+// each as JSON on stdin and collecting its PluginResponse from stdout.
+// Plugins that don't declare hook are skipped, so existing script-style
+// plugins, which only understand argv and raw stdio, are unaffected.
+func Dispatch(ctx context.Context, hook Hook, payload PluginInvocation) ([]PluginResponse, error) {
+	plugins, err := LoadAll()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to load the installed plugins: %w", err)
+	}
+
+	payload.Hook = hook
+
+	var responses []PluginResponse
+	for _, p := range plugins {
+		if !p.handles(hook) {
+			continue
+		}
+
+		resp, err := p.invoke(ctx, payload)
+		if err != nil {
+			return nil, xerrors.Errorf("plugin %s failed on hook %s: %w", p.Name, hook, err)
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// handles reports whether p declared hook in plugin.yaml.
+func (p Plugin) handles(hook Hook) bool {
+	for _, h := range p.Hooks {
+		if Hook(h) == hook {
+			return true
+		}
+	}
+	return false
+}
+
+// invoke runs p's platform binary with payload JSON on stdin and parses its
+// stdout as a PluginResponse.
+func (p Plugin) invoke(ctx context.Context, payload PluginInvocation) (PluginResponse, error) {
+	dir, err := pluginInstallDir(p)
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	platform, err := p.selectPlatform()
+	if err != nil {
+		return PluginResponse{}, err
+	}
+
+	in, err := json.Marshal(payload)
+	if err != nil {
+		return PluginResponse{}, xerrors.Errorf("unable to marshal the plugin invocation: %w", err)
+	}
+
+	cmd, cleanup := sandboxCommand(ctx, filepath.Join(dir, platform.Bin), nil, p.Permissions)
+	defer cleanup()
+	cmd.Stdin = bytes.NewReader(in)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err = runSandboxedCommand(cmd, p.Permissions); err != nil {
+		return PluginResponse{}, xerrors.Errorf("plugin exec: %w: %s", err, stderr.String())
+	}
+
+	var resp PluginResponse
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return PluginResponse{}, xerrors.Errorf("unable to parse the plugin response: %w", err)
+	}
+
+	log.Logger.Debugf("plugin %s handled hook %s", p.Name, payload.Hook)
+	return resp, nil
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginHandles(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks []string
+		check Hook
+		want  bool
+	}{
+		{
+			name:  "declared hook",
+			hooks: []string{"postScan", "output"},
+			check: HookPostScan,
+			want:  true,
+		},
+		{
+			name:  "undeclared hook",
+			hooks: []string{"output"},
+			check: HookPostScan,
+			want:  false,
+		},
+		{
+			name:  "script-style plugin with no hooks",
+			hooks: nil,
+			check: HookPostScan,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Plugin{Hooks: tt.hooks}
+			assert.Equal(t, tt.want, p.handles(tt.check))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// permissionsAcceptedFile marks that a user has reviewed and approved a
+// plugin's declared Permissions, mirroring Docker's plugin "Privileges"
+// confirmation flow.
+const permissionsAcceptedFile = ".permissions-accepted"
+
+// Permissions declares what a plugin is allowed to touch when it runs.
+// Trivy plugins are executables invoked directly by p.Run, so without this
+// they inherit the full user environment and can exfiltrate credentials
+// silently; declaring Permissions lets Run confine them instead.
+type Permissions struct {
+	// FilesystemRead lists paths the plugin may read.
+	FilesystemRead []string `yaml:"filesystemRead,omitempty"`
+	// FilesystemWrite lists paths the plugin may read and write.
+	FilesystemWrite []string `yaml:"filesystemWrite,omitempty"`
+	// NetworkHosts lists hosts the plugin may reach. Hosts are not filtered
+	// individually: on Linux and macOS a non-empty list allows network access
+	// to any host and an empty list means no network access at all; on
+	// Windows network access is not restricted.
+	NetworkHosts []string `yaml:"networkHosts,omitempty"`
+	// Env lists environment variables passed through to the plugin; any
+	// variable not listed here is stripped before exec.
+	Env []string `yaml:"env,omitempty"`
+	// Subprocess allows the plugin to spawn its own child processes. On Linux
+	// it only shares the host PID namespace with the plugin; false does not
+	// stop the plugin from starting child processes. On Windows it has no
+	// effect.
+	Subprocess bool `yaml:"subprocess,omitempty"`
+}
+
+// AcceptPermissions records that the user has reviewed and approved p's
+// declared Permissions. Run refuses to execute a plugin with unaccepted
+// Permissions.
+func AcceptPermissions(p Plugin) error {
+	path, err := permissionsAcceptedPath(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte("accepted\n"), 0o644)
+}
+
+func permissionsAccepted(p Plugin) bool {
+	path, err := permissionsAcceptedPath(p)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// permissionsAcceptedPath resolves the acceptance marker against p's actual
+// install dir (see pluginInstallDir), not always the user's own plugin dir,
+// so a plugin found via TRIVY_PLUGIN_PATH or the system dir records and
+// checks acceptance where it actually lives.
+func permissionsAcceptedPath(p Plugin) (string, error) {
+	dir, err := pluginInstallDir(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, permissionsAcceptedFile), nil
+}
+
+// sandboxCommand builds the command used to run execFile with args, confined
+// to perms when set. A nil perms runs the plugin exactly as before this
+// feature existed, inheriting the full environment unsandboxed. The returned
+// func must be called (typically via defer) once the command has finished,
+// to clean up any on-disk artifacts (e.g. macOS's generated sandbox-exec
+// profile) the sandboxing needed.
+func sandboxCommand(ctx context.Context, execFile string, args []string, perms *Permissions) (*exec.Cmd, func()) {
+	if perms == nil {
+		return exec.CommandContext(ctx, execFile, args...), func() {}
+	}
+	return newSandboxedCommand(ctx, execFile, args, perms)
+}
+
+// filteredEnv returns the subset of the current environment whose variable
+// names appear in allowlist. An empty allowlist strips the environment
+// entirely rather than passing everything through.
+func filteredEnv(allowlist []string) []string {
+	if len(allowlist) == 0 {
+		return []string{}
+	}
+
+	allowed := make(map[string]bool, len(allowlist))
+	for _, k := range allowlist {
+		allowed[k] = true
+	}
+
+	env := make([]string, 0, len(allowlist))
+	for _, kv := range os.Environ() {
+		if k, _, ok := strings.Cut(kv, "="); ok && allowed[k] {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
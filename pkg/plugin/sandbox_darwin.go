@@ -0,0 +1,74 @@
+//go:build darwin
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// newSandboxedCommand confines the plugin with a generated sandbox-exec(1)
+// profile. If the profile can't be written, it runs the plugin unconfined
+// with a loud warning rather than refusing to execute a plugin the user
+// already chose to install. The returned cleanup func removes the generated
+// profile file; callers must invoke it once the command has finished.
+func newSandboxedCommand(ctx context.Context, execFile string, args []string, perms *Permissions) (*exec.Cmd, func()) {
+	profile, err := writeSandboxProfile(filepath.Dir(execFile), perms)
+	if err != nil {
+		log.Logger.Warnf("unable to build a sandbox-exec profile, running %s unsandboxed: %s", execFile, err)
+		cmd := exec.CommandContext(ctx, execFile, args...)
+		cmd.Env = filteredEnv(perms.Env)
+		return cmd, func() {}
+	}
+
+	sbArgs := append([]string{"-f", profile, execFile}, args...)
+	cmd := exec.CommandContext(ctx, "sandbox-exec", sbArgs...)
+	cmd.Env = filteredEnv(perms.Env)
+	return cmd, func() { _ = os.Remove(profile) }
+}
+
+// writeSandboxProfile builds a sandbox-exec profile confining the plugin to
+// perms, always allowing read and exec access to pluginDir so the plugin's
+// own binary (and any dynamic libraries alongside it) can actually be
+// loaded; without it (deny default) blocks the plugin before it even starts.
+func writeSandboxProfile(pluginDir string, perms *Permissions) (string, error) {
+	rules := []string{
+		"(version 1)",
+		"(deny default)",
+		fmt.Sprintf(`(allow file-read* (subpath %q))`, pluginDir),
+		fmt.Sprintf(`(allow process-exec* (subpath %q))`, pluginDir),
+	}
+
+	for _, path := range perms.FilesystemRead {
+		rules = append(rules, fmt.Sprintf(`(allow file-read* (subpath %q))`, path))
+	}
+	for _, path := range perms.FilesystemWrite {
+		rules = append(rules, fmt.Sprintf(`(allow file-write* (subpath %q))`, path))
+		rules = append(rules, fmt.Sprintf(`(allow file-read* (subpath %q))`, path))
+	}
+	if len(perms.NetworkHosts) > 0 {
+		rules = append(rules, "(allow network*)")
+	}
+	if perms.Subprocess {
+		rules = append(rules, "(allow process-exec*)")
+	}
+
+	f, err := os.CreateTemp("", "trivy-plugin-*.sb")
+	if err != nil {
+		return "", xerrors.Errorf("unable to create the sandbox profile: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = f.WriteString(strings.Join(rules, "\n") + "\n"); err != nil {
+		return "", xerrors.Errorf("unable to write the sandbox profile: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilteredEnv(t *testing.T) {
+	t.Setenv("TRIVY_PLUGIN_TEST_ALLOWED", "yes")
+	t.Setenv("TRIVY_PLUGIN_TEST_BLOCKED", "no")
+
+	env := filteredEnv([]string{"TRIVY_PLUGIN_TEST_ALLOWED"})
+	assert.Contains(t, env, "TRIVY_PLUGIN_TEST_ALLOWED=yes")
+	assert.NotContains(t, env, "TRIVY_PLUGIN_TEST_BLOCKED=no")
+}
+
+func TestFilteredEnvEmptyAllowlist(t *testing.T) {
+	t.Setenv("TRIVY_PLUGIN_TEST_ALLOWED", "yes")
+	assert.Empty(t, filteredEnv(nil))
+}
+
+func TestPermissionsAcceptance(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	p := Plugin{Name: "test_plugin"}
+	assert.False(t, permissionsAccepted(p))
+
+	dir, err := PluginDir(p.Name)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(dir, os.ModePerm))
+
+	assert.NoError(t, AcceptPermissions(p))
+	assert.True(t, permissionsAccepted(p))
+}
+
+func TestPermissionsAcceptanceOutsideUserDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	systemDir := t.TempDir()
+	p := Plugin{Name: "test_plugin", Dir: systemDir}
+	assert.False(t, permissionsAccepted(p))
+
+	dir, err := pluginInstallDir(p)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(dir, os.ModePerm))
+
+	assert.NoError(t, AcceptPermissions(p))
+	assert.True(t, permissionsAccepted(p))
+
+	// The marker must land under the declared Dir, not the user's own
+	// plugin dir, or a plugin installed outside it could never pass Run's
+	// permissions check.
+	userDir, err := PluginDir(p.Name)
+	assert.NoError(t, err)
+	assert.NoDirExists(t, userDir)
+}
@@ -0,0 +1,55 @@
+//go:build linux
+
+package plugin
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+)
+
+// newSandboxedCommand confines the plugin with bubblewrap (bwrap): besides
+// read-only /usr, /lib, /lib64 and the plugin's own dir, only declared
+// filesystem paths are bind-mounted, all namespaces are unshared by
+// default, and capabilities are dropped. bwrap must be on PATH; if it isn't,
+// the plugin fails to start rather than silently running unconfined.
+func newSandboxedCommand(ctx context.Context, execFile string, args []string, perms *Permissions) (*exec.Cmd, func()) {
+	pluginDir := filepath.Dir(execFile)
+	bwrapArgs := []string{
+		"--ro-bind", "/usr", "/usr",
+		"--ro-bind", "/lib", "/lib",
+		"--ro-bind", "/lib64", "/lib64",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		// The plugin's own install dir holds execFile, so it must be
+		// reachable regardless of what the plugin declares; otherwise bwrap
+		// can never find the binary it was asked to run.
+		"--ro-bind", pluginDir, pluginDir,
+		"--unshare-all",
+		"--die-with-parent",
+		"--cap-drop", "ALL",
+	}
+
+	for _, path := range perms.FilesystemRead {
+		bwrapArgs = append(bwrapArgs, "--ro-bind", path, path)
+	}
+	for _, path := range perms.FilesystemWrite {
+		bwrapArgs = append(bwrapArgs, "--bind", path, path)
+	}
+	if len(perms.NetworkHosts) > 0 {
+		// bwrap can't filter by host itself; re-share the net namespace and
+		// rely on the declared NetworkHosts being enforced by the caller's
+		// egress policy (e.g. a NetworkPolicy in CI).
+		bwrapArgs = append(bwrapArgs, "--share-net")
+	}
+	if perms.Subprocess {
+		bwrapArgs = append(bwrapArgs, "--share-pid")
+	}
+
+	bwrapArgs = append(bwrapArgs, "--", execFile)
+	bwrapArgs = append(bwrapArgs, args...)
+
+	cmd := exec.CommandContext(ctx, "bwrap", bwrapArgs...)
+	cmd.Env = filteredEnv(perms.Env)
+	return cmd, func() {}
+}
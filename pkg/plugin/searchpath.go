@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"golang.org/x/xerrors"
+
+	"github.com/aquasecurity/trivy/pkg/log"
+)
+
+// pluginPathEnvVar lets users (and air-gapped or shared-NFS setups) point
+// Trivy at additional plugin directories, mirroring PACKER_PLUGIN_PATH and
+// Helm's plugdirs.
+const pluginPathEnvVar = "TRIVY_PLUGIN_PATH"
+
+// systemPluginDir is a built-in, read-mostly location for distro or
+// Homebrew packaged plugins, checked after every user-configured directory.
+func systemPluginDir() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "trivy", "plugins")
+	}
+	return "/usr/local/share/trivy/plugins"
+}
+
+// pluginSearchPath returns every directory LoadAll (and, through it,
+// Information and Uninstall) looks in, in precedence order: each
+// TRIVY_PLUGIN_PATH entry, then the user's own XDG_DATA_HOME plugins dir,
+// then the built-in system dir.
+func pluginSearchPath() ([]string, error) {
+	var dirs []string
+	if p := os.Getenv(pluginPathEnvVar); p != "" {
+		dirs = append(dirs, filepath.SplitList(p)...)
+	}
+
+	dataHome, err := dataHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dirs = append(dirs, filepath.Join(dataHome, ".trivy", "plugins"))
+	dirs = append(dirs, systemPluginDir())
+
+	return dirs, nil
+}
+
+// loadAllFrom loads every plugin found across dirs, deduplicated by name
+// with earlier directories taking precedence. A missing directory is
+// skipped rather than treated as an error, since most of the search path is
+// optional by design. A subdirectory without a readable plugin.yaml (a
+// stray ".git", "lost+found", or a half-extracted plugin) is likewise
+// skipped (logged at debug level) instead of failing the whole walk, since a shared
+// system dir or a user-supplied TRIVY_PLUGIN_PATH entry isn't guaranteed to
+// contain only plugins.
+func loadAllFrom(dirs []string) ([]Plugin, error) {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, base := range dirs {
+		entries, err := os.ReadDir(base)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, xerrors.Errorf("unable to read %s: %w", base, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || seen[entry.Name()] {
+				continue
+			}
+
+			dir := filepath.Join(base, entry.Name())
+			plugin, err := loadMetadata(dir)
+			if err != nil {
+				log.Logger.Debugf("skipping %s, it doesn't look like a plugin: %s", dir, err)
+				continue
+			}
+			plugin.GOOS = runtime.GOOS
+			plugin.GOARCH = runtime.GOARCH
+			plugin.Dir = base
+
+			seen[entry.Name()] = true
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	return plugins, nil
+}
@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestPlugin(t *testing.T, dir, name string) {
+	t.Helper()
+	pluginDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(pluginDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, metadataFile), []byte("name: "+name+"\n"), os.ModePerm))
+}
+
+func TestLoadAllFromPrecedence(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+
+	// "shared" exists in both dirs; the first dir should win.
+	writeTestPlugin(t, first, "shared")
+	writeTestPlugin(t, first, "only-in-first")
+	writeTestPlugin(t, second, "shared")
+	writeTestPlugin(t, second, "only-in-second")
+
+	plugins, err := loadAllFrom([]string{first, second})
+	require.NoError(t, err)
+
+	byName := make(map[string]Plugin, len(plugins))
+	for _, p := range plugins {
+		byName[p.Name] = p
+	}
+
+	require.Contains(t, byName, "shared")
+	assert.Equal(t, first, byName["shared"].Dir)
+	assert.Equal(t, first, byName["only-in-first"].Dir)
+	assert.Equal(t, second, byName["only-in-second"].Dir)
+	assert.Len(t, plugins, 3)
+}
+
+func TestLoadAllFromSkipsMissingDirs(t *testing.T) {
+	plugins, err := loadAllFrom([]string{filepath.Join(t.TempDir(), "does-not-exist")})
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+func TestLoadAllFromSkipsEntriesWithoutMetadata(t *testing.T) {
+	base := t.TempDir()
+	writeTestPlugin(t, base, "good")
+	require.NoError(t, os.MkdirAll(filepath.Join(base, ".git"), os.ModePerm))
+
+	plugins, err := loadAllFrom([]string{base})
+	require.NoError(t, err)
+
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "good", plugins[0].Name)
+}
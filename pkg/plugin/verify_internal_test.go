@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyArtifact(t *testing.T) {
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "test.sh")
+	require.NoError(t, os.WriteFile(binPath, []byte("#!/bin/sh\necho hi\n"), 0o755))
+
+	// sha256("#!/bin/sh\necho hi\n")
+	const wantSHA256 = "299001868fb8c02fd431c336c6d058f5558c5dff5b5af5e6fe04b870a6a9cbba"
+
+	tests := []struct {
+		name     string
+		platform Platform
+		insecure bool
+		wantErr  string
+	}{
+		{
+			name:     "matching checksum",
+			platform: Platform{SHA256: wantSHA256},
+		},
+		{
+			name:     "mismatched checksum",
+			platform: Platform{SHA256: "deadbeef"},
+			wantErr:  "checksum mismatch",
+		},
+		{
+			name:     "no checksum or signature, insecure",
+			platform: Platform{},
+			insecure: true,
+		},
+		{
+			name:     "no checksum or signature, refused",
+			platform: Platform{},
+			wantErr:  "--plugin-insecure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyArtifact(binPath, tt.platform, tt.insecure)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
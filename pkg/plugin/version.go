@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/xerrors"
+)
+
+// splitVersionConstraint splits a "url@constraint" string (mkr-style) into
+// its url and constraint parts, e.g. "repo@v1.2.0" or "repo@^1.2". A url
+// with no "@" is returned unchanged with an empty constraint.
+//
+// Only an "@" in the final path segment counts: go-getter also accepts
+// userinfo "@"s in the host portion of URLs like
+// "ssh://git@github.com/org/repo.git" or "user@host:path/repo.git", and
+// those must keep working rather than being mistaken for a version pin.
+func splitVersionConstraint(url string) (string, string) {
+	idx := strings.LastIndex(url, "@")
+	if idx <= 0 {
+		return url, ""
+	}
+	if slash := strings.LastIndex(url, "/"); slash > idx {
+		return url, ""
+	}
+	return url[:idx], url[idx+1:]
+}
+
+// resolveVersion returns the highest released tag of repoURL that satisfies
+// constraint (a semver constraint such as "v1.2.0" or "^1.2").
+func resolveVersion(ctx context.Context, repoURL, constraint string) (string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", xerrors.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	tags, err := listTags(ctx, repoURL)
+	if err != nil {
+		return "", xerrors.Errorf("unable to list released versions: %w", err)
+	}
+
+	var best *semver.Version
+	var bestTag string
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil {
+			// Skip tags that aren't semver, e.g. "latest-docs".
+			continue
+		}
+		if !c.Check(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best = v
+			bestTag = tag
+		}
+	}
+
+	if best == nil {
+		return "", xerrors.Errorf("no version of %s satisfies %q", repoURL, constraint)
+	}
+	return bestTag, nil
+}
+
+// listTags enumerates the available release tags for repoURL: the GitHub
+// releases API for github.com/... sources, or plain git tags otherwise.
+func listTags(ctx context.Context, repoURL string) ([]string, error) {
+	if owner, repo, ok := githubOwnerRepo(repoURL); ok {
+		return listGitHubReleaseTags(ctx, owner, repo)
+	}
+	return listGitTags(ctx, repoURL)
+}
+
+// githubOwnerRepo extracts the owner/repo from a "github.com/owner/repo..."
+// style URL.
+func githubOwnerRepo(repoURL string) (owner, repo string, ok bool) {
+	u := strings.TrimPrefix(repoURL, "https://")
+	u = strings.TrimPrefix(u, "http://")
+	if !strings.HasPrefix(u, "github.com/") {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(u, "github.com/"), "/", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
+
+func listGitHubReleaseTags(ctx context.Context, owner, repo string) ([]string, error) {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to build the GitHub releases request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("unable to call the GitHub releases API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("GitHub releases API returned status %d", resp.StatusCode)
+	}
+
+	var releases []struct {
+		TagName string `json:"tag_name"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, xerrors.Errorf("unable to parse the GitHub releases response: %w", err)
+	}
+
+	tags := make([]string, 0, len(releases))
+	for _, r := range releases {
+		tags = append(tags, r.TagName)
+	}
+	return tags, nil
+}
+
+func listGitTags(ctx context.Context, repoURL string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "ls-remote", "--tags", repoURL).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("unable to list git tags for %s: %w", repoURL, err)
+	}
+
+	var tags []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		ref := strings.TrimSuffix(fields[1], "^{}")
+		if tag, found := strings.CutPrefix(ref, "refs/tags/"); found {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
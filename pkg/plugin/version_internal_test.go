@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitVersionConstraint(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		wantURL        string
+		wantConstraint string
+	}{
+		{
+			name:           "pinned version",
+			url:            "github.com/aquasecurity/trivy-plugin-test@v1.2.0",
+			wantURL:        "github.com/aquasecurity/trivy-plugin-test",
+			wantConstraint: "v1.2.0",
+		},
+		{
+			name:           "minor line constraint",
+			url:            "github.com/aquasecurity/trivy-plugin-test@^1.2",
+			wantURL:        "github.com/aquasecurity/trivy-plugin-test",
+			wantConstraint: "^1.2",
+		},
+		{
+			name:    "no constraint",
+			url:     "github.com/aquasecurity/trivy-plugin-test",
+			wantURL: "github.com/aquasecurity/trivy-plugin-test",
+		},
+		{
+			name:    "ssh url with userinfo is not mistaken for a constraint",
+			url:     "ssh://git@github.com/aquasecurity/trivy-plugin-test.git",
+			wantURL: "ssh://git@github.com/aquasecurity/trivy-plugin-test.git",
+		},
+		{
+			name:           "ssh url with userinfo and a trailing constraint",
+			url:            "ssh://git@github.com/aquasecurity/trivy-plugin-test.git@v1.2.0",
+			wantURL:        "ssh://git@github.com/aquasecurity/trivy-plugin-test.git",
+			wantConstraint: "v1.2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, constraint := splitVersionConstraint(tt.url)
+			assert.Equal(t, tt.wantURL, url)
+			assert.Equal(t, tt.wantConstraint, constraint)
+		})
+	}
+}
+
+func TestGithubOwnerRepo(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantOK    bool
+	}{
+		{
+			name:      "plain github url",
+			url:       "github.com/aquasecurity/trivy-plugin-test",
+			wantOwner: "aquasecurity",
+			wantRepo:  "trivy-plugin-test",
+			wantOK:    true,
+		},
+		{
+			name:      "with scheme and .git suffix",
+			url:       "https://github.com/aquasecurity/trivy-plugin-test.git",
+			wantOwner: "aquasecurity",
+			wantRepo:  "trivy-plugin-test",
+			wantOK:    true,
+		},
+		{
+			name:   "non-github url",
+			url:    "gitlab.com/aquasecurity/trivy-plugin-test",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, ok := githubOwnerRepo(tt.url)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantOwner, owner)
+				assert.Equal(t, tt.wantRepo, repo)
+			}
+		})
+	}
+}